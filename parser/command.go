@@ -0,0 +1,331 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Command represents a named subcommand with its own flag set, its own
+// positional arguments, and an optional handler invoked by Parser.Execute.
+// Commands may be nested via AddCommand to build tools like `mytool remote
+// add <name> <url>`.
+type Command struct {
+	name        string // Command name as typed on the command line
+	description string // Short, one-line description shown in command lists
+	longDesc    string // Longer description shown in this command's usage
+
+	flags      []flagInfo // Flags registered on this command
+	positional []string   // Residual positional arguments after flag parsing
+
+	commands []*Command // Nested subcommands
+	matched  *Command   // Nested subcommand selected by the most recent parse
+
+	parent *Command  // Parent command, nil for top-level commands
+	parser *Parser   // Root parser, used for the program name and output
+	output io.Writer // Where to write this command's usage
+
+	// Run is invoked with the command's residual positional arguments when
+	// this command is the one ultimately selected by Parser.Parse and
+	// Parser.Execute is called.
+	Run func(args []string) error
+}
+
+// StringVar registers a string flag on this command.
+func (c *Command) StringVar(ptr *string, shortName, longName string, defaultValue string, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newStringValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// IntVar registers an integer flag on this command.
+func (c *Command) IntVar(ptr *int, shortName, longName string, defaultValue int, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newIntValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// BoolVar registers a boolean flag on this command.
+func (c *Command) BoolVar(ptr *bool, shortName, longName string, defaultValue bool, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newBoolValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// StringVarE registers a string flag on this command with an
+// environment-variable fallback and/or required-flag enforcement; see
+// Parser.StringVarE.
+func (c *Command) StringVarE(ptr *string, shortName, longName, envVar string, defaultValue string, description string, required bool) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newStringValue(defaultValue, ptr), defaultValue: defaultValue, description: description, envVar: envVar, required: required})
+}
+
+// IntVarE is IntVar's counterpart on this command with environment-variable
+// fallback and required-flag enforcement; see Parser.StringVarE.
+func (c *Command) IntVarE(ptr *int, shortName, longName, envVar string, defaultValue int, description string, required bool) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newIntValue(defaultValue, ptr), defaultValue: defaultValue, description: description, envVar: envVar, required: required})
+}
+
+// BoolVarE is BoolVar's counterpart on this command with environment-variable
+// fallback and required-flag enforcement; see Parser.StringVarE.
+func (c *Command) BoolVarE(ptr *bool, shortName, longName, envVar string, defaultValue bool, description string, required bool) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newBoolValue(defaultValue, ptr), defaultValue: defaultValue, description: description, envVar: envVar, required: required})
+}
+
+// Float64Var registers a float64 flag on this command.
+func (c *Command) Float64Var(ptr *float64, shortName, longName string, defaultValue float64, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newFloat64Value(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// Int64Var registers an int64 flag on this command.
+func (c *Command) Int64Var(ptr *int64, shortName, longName string, defaultValue int64, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newInt64Value(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// UintVar registers a uint flag on this command.
+func (c *Command) UintVar(ptr *uint, shortName, longName string, defaultValue uint, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newUintValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// Uint64Var registers a uint64 flag on this command.
+func (c *Command) Uint64Var(ptr *uint64, shortName, longName string, defaultValue uint64, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newUint64Value(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// DurationVar registers a time.Duration flag on this command, parsed with
+// time.ParseDuration.
+func (c *Command) DurationVar(ptr *time.Duration, shortName, longName string, defaultValue time.Duration, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newDurationValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// StringSliceVar registers a repeatable string flag on this command. Each
+// occurrence's raw value is split on commas and appended.
+func (c *Command) StringSliceVar(ptr *[]string, shortName, longName string, defaultValue []string, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newStringSliceValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// StringArrayVar registers a repeatable string flag on this command, like
+// StringSliceVar, except each occurrence's raw value is kept verbatim
+// rather than split on commas.
+func (c *Command) StringArrayVar(ptr *[]string, shortName, longName string, defaultValue []string, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newStringArrayValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// IntSliceVar registers a repeatable int flag on this command,
+// comma-splitting each occurrence's raw value.
+func (c *Command) IntSliceVar(ptr *[]int, shortName, longName string, defaultValue []int, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newIntSliceValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// BoolSliceVar registers a repeatable bool flag on this command,
+// comma-splitting each occurrence's raw value.
+func (c *Command) BoolSliceVar(ptr *[]bool, shortName, longName string, defaultValue []bool, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newBoolSliceValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// DurationSliceVar registers a repeatable time.Duration flag on this
+// command, comma-splitting each occurrence's raw value.
+func (c *Command) DurationSliceVar(ptr *[]time.Duration, shortName, longName string, defaultValue []time.Duration, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newDurationSliceValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// CountVar registers a flag on this command whose backing int increments by
+// one on every occurrence, for verbosity-style flags like `-vvv`.
+func (c *Command) CountVar(ptr *int, shortName, longName string, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: newCountValue(0, ptr), defaultValue: 0, description: description})
+}
+
+// Var registers a user-defined flag value on this command.
+func (c *Command) Var(v Value, shortName, longName, description string) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: v, description: description})
+}
+
+// FuncVar registers a flag on this command that invokes fn with the raw
+// string argument instead of storing into a backing variable.
+func (c *Command) FuncVar(shortName, longName, description string, fn func(string) error) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: funcValue(fn), description: description})
+}
+
+// BoolFuncVar is FuncVar's boolean-flag counterpart for this command.
+func (c *Command) BoolFuncVar(shortName, longName, description string, fn func(string) error) {
+	c.flags = append(c.flags, flagInfo{shortName: shortName, longName: longName, value: boolFuncValue(fn), description: description})
+}
+
+// AddCommand registers a nested subcommand under c.
+func (c *Command) AddCommand(name, description string) *Command {
+	sub := &Command{
+		name:        name,
+		description: description,
+		parser:      c.parser,
+		parent:      c,
+		output:      c.output,
+	}
+	c.commands = append(c.commands, sub)
+	return sub
+}
+
+// SetLongDescription sets the longer description shown in this command's
+// usage output, in addition to the short description passed to AddCommand.
+func (c *Command) SetLongDescription(description string) {
+	c.longDesc = description
+}
+
+// Args returns the positional arguments left over after this command's
+// flags were parsed.
+func (c *Command) Args() []string {
+	return c.positional
+}
+
+// VisitAll calls fn for every flag registered on this command, in
+// registration order, regardless of whether it was set; see Parser.VisitAll.
+func (c *Command) VisitAll(fn func(shortName, longName string, value Value)) {
+	for _, fi := range c.flags {
+		fn(fi.shortName, fi.longName, fi.value)
+	}
+}
+
+// Visit calls fn for every flag registered on this command that was set,
+// either on the command line or via its envVar fallback; see Parser.Visit.
+func (c *Command) Visit(fn func(shortName, longName string, value Value)) {
+	for _, fi := range c.flags {
+		if fi.wasSet {
+			fn(fi.shortName, fi.longName, fi.value)
+		}
+	}
+}
+
+// parse parses args against c's flags. If c has nested subcommands, it
+// parses c's own flags up to the first non-flag token and dispatches the
+// remainder to the matched subcommand, the same way Parser.Parse does.
+func (c *Command) parse(args []string) error {
+	if len(c.commands) == 0 {
+		beforeTerminator := args
+		if idx := indexOf(args, "--"); idx != -1 {
+			beforeTerminator = args[:idx]
+		}
+		if contains(beforeTerminator, "--help") || contains(beforeTerminator, "-h") {
+			c.Usage()
+			return ErrHelpRequested
+		}
+
+		positional, err := parseArgs(c.flags, args)
+		if err != nil {
+			return err
+		}
+		c.positional = positional
+		return nil
+	}
+
+	ownArgs, rest := splitGlobalArgs(c.flags, args)
+
+	beforeTerminator := ownArgs
+	if idx := indexOf(ownArgs, "--"); idx != -1 {
+		beforeTerminator = ownArgs[:idx]
+	}
+	if contains(beforeTerminator, "--help") || contains(beforeTerminator, "-h") {
+		c.Usage()
+		return ErrHelpRequested
+	}
+
+	positional, err := parseArgs(c.flags, ownArgs)
+	if err != nil {
+		return err
+	}
+	c.positional = positional
+
+	if len(rest) == 0 {
+		return fmt.Errorf("no subcommand specified for %q; available commands: %s", c.name, commandNames(c.commands))
+	}
+
+	name, tail := rest[0], rest[1:]
+
+	if name == "help" {
+		if len(tail) == 0 {
+			c.Usage()
+			return ErrHelpRequested
+		}
+		sub, ok := findCommand(c.commands, tail[0])
+		if !ok {
+			return fmt.Errorf("unknown command %q; available commands: %s", tail[0], commandNames(c.commands))
+		}
+		return sub.helpFor(tail[1:])
+	}
+
+	sub, ok := findCommand(c.commands, name)
+	if !ok {
+		return fmt.Errorf("unknown command %q; available commands: %s", name, commandNames(c.commands))
+	}
+
+	c.matched = sub
+	return sub.parse(tail)
+}
+
+// helpFor prints usage for c, or for the subcommand named by the first
+// element of names if any are given (recursing for further nesting).
+func (c *Command) helpFor(names []string) error {
+	if len(names) == 0 {
+		c.Usage()
+		return ErrHelpRequested
+	}
+	sub, ok := findCommand(c.commands, names[0])
+	if !ok {
+		return fmt.Errorf("unknown command %q; available commands: %s", names[0], commandNames(c.commands))
+	}
+	return sub.helpFor(names[1:])
+}
+
+// execute invokes the leaf command's Run function, following matched
+// subcommands down the chain picked by the most recent parse.
+func (c *Command) execute() error {
+	if c.matched != nil {
+		return c.matched.execute()
+	}
+	if c.Run == nil {
+		return fmt.Errorf("command %q has no Run function", c.fullName())
+	}
+	return c.Run(c.positional)
+}
+
+// fullName returns the command's name prefixed by its parents and the
+// program name, e.g. "mytool remote add".
+func (c *Command) fullName() string {
+	prefix := c.name
+	if c.parent != nil {
+		return c.parent.fullName() + " " + c.name
+	}
+	if c.parser != nil {
+		return c.parser.program + " " + prefix
+	}
+	return prefix
+}
+
+// Usage writes this command's usage: its own flags, its long description if
+// set, and a list of its nested subcommands if any.
+func (c *Command) Usage() {
+	fmt.Fprintf(c.output, "Usage of %s:\n", c.fullName())
+	if c.longDesc != "" {
+		fmt.Fprintf(c.output, "\n%s\n", c.longDesc)
+	}
+	if len(c.flags) > 0 {
+		fmt.Fprintln(c.output)
+		writeFlagUsage(c.output, c.flags)
+	}
+	if len(c.commands) > 0 {
+		fmt.Fprintln(c.output, "\nAvailable commands:")
+		writeCommandList(c.output, c.commands)
+	}
+}
+
+// findCommand looks up a subcommand by name.
+func findCommand(commands []*Command, name string) (*Command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// commandNames renders a comma-separated list of command names, used in
+// "unknown command" errors and the "no command specified" error.
+func commandNames(commands []*Command) string {
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommandDispatch(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewParser(out)
+	p.SetProgramName("mytool")
+
+	var force bool
+	install := p.AddCommand("install", "Install a package")
+	install.BoolVar(&force, "f", "force", false, "Overwrite existing files")
+
+	err := p.Parse([]string{"install", "--force", "pkg"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !force {
+		t.Error("Expected force to be true")
+	}
+	if !reflect.DeepEqual(install.Args(), []string{"pkg"}) {
+		t.Errorf("Expected command args [pkg], got %v", install.Args())
+	}
+}
+
+func TestCommandExecute(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewParser(out)
+
+	var ran []string
+	install := p.AddCommand("install", "Install a package")
+	install.Run = func(args []string) error {
+		ran = args
+		return nil
+	}
+
+	if err := p.Parse([]string{"install", "pkg"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := p.Execute(); err != nil {
+		t.Fatalf("Unexpected error from Execute: %v", err)
+	}
+	if !reflect.DeepEqual(ran, []string{"pkg"}) {
+		t.Errorf("Expected Run to receive [pkg], got %v", ran)
+	}
+}
+
+func TestNestedCommands(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewParser(out)
+
+	var name, url string
+	remote := p.AddCommand("remote", "Manage remotes")
+	add := remote.AddCommand("add", "Add a remote")
+	add.Run = func(args []string) error {
+		name, url = args[0], args[1]
+		return nil
+	}
+
+	if err := p.Parse([]string{"remote", "add", "origin", "git://example.com"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := p.Execute(); err != nil {
+		t.Fatalf("Unexpected error from Execute: %v", err)
+	}
+	if name != "origin" || url != "git://example.com" {
+		t.Errorf("Expected origin/git://example.com, got %s/%s", name, url)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewParser(out)
+	p.AddCommand("install", "Install a package")
+	p.AddCommand("remove", "Remove a package")
+
+	err := p.Parse([]string{"bogus"})
+	if err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Fatalf("Expected unknown command error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "install") || !strings.Contains(err.Error(), "remove") {
+		t.Errorf("Expected candidate commands listed, got %v", err)
+	}
+}
+
+func TestCommandHelp(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewParser(out)
+	p.SetProgramName("mytool")
+
+	var force bool
+	install := p.AddCommand("install", "Install a package")
+	install.BoolVar(&force, "f", "force", false, "Overwrite existing files")
+
+	err := p.Parse([]string{"help", "install"})
+	if err != ErrHelpRequested {
+		t.Fatalf("Expected ErrHelpRequested, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Usage of mytool install:") {
+		t.Errorf("Expected command usage header, got %s", out.String())
+	}
+	if !strings.Contains(out.String(), "-f, --force") {
+		t.Errorf("Expected force flag listed, got %s", out.String())
+	}
+}
+
+func TestCommandHelpAfterGlobalFlag(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewParser(out)
+	p.SetProgramName("mytool")
+
+	var verbose bool
+	p.BoolVar(&verbose, "v", "verbose", false, "Verbose output")
+	p.AddCommand("install", "Install a package")
+
+	err := p.Parse([]string{"-v", "--help"})
+	if err != ErrHelpRequested {
+		t.Fatalf("Expected ErrHelpRequested, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Usage of mytool:") {
+		t.Errorf("Expected root usage header, got %s", out.String())
+	}
+}
+
+func TestCommandHelpAfterOwnFlag(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewParser(out)
+	p.SetProgramName("mytool")
+
+	var force bool
+	install := p.AddCommand("install", "Install a package")
+	install.BoolVar(&force, "f", "force", false, "Overwrite existing files")
+	install.AddCommand("nested", "Nested subcommand")
+
+	err := p.Parse([]string{"install", "-f", "--help"})
+	if err != ErrHelpRequested {
+		t.Fatalf("Expected ErrHelpRequested, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Usage of mytool install:") {
+		t.Errorf("Expected command usage header, got %s", out.String())
+	}
+}
+
+func TestRootUsageListsCommands(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewParser(out)
+	p.SetProgramName("mytool")
+	p.AddCommand("install", "Install a package")
+	p.AddCommand("remove", "Remove a package")
+
+	p.Usage()
+
+	if !strings.Contains(out.String(), "Available commands:") {
+		t.Errorf("Expected commands block, got %s", out.String())
+	}
+	if !strings.Contains(out.String(), "install") || !strings.Contains(out.String(), "remove") {
+		t.Errorf("Expected both commands listed, got %s", out.String())
+	}
+}
+
+func TestNoCommandSpecified(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewParser(out)
+	p.AddCommand("install", "Install a package")
+
+	err := p.Parse([]string{})
+	if err == nil || !strings.Contains(err.Error(), "no command specified") {
+		t.Fatalf("Expected no command specified error, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStringVarEEnvFallback(t *testing.T) {
+	os.Setenv("GOARGS_TEST_HOST", "example.com")
+	defer os.Unsetenv("GOARGS_TEST_HOST")
+
+	p := NewParser(&bytes.Buffer{})
+	var host string
+	p.StringVarE(&host, "", "host", "GOARGS_TEST_HOST", "localhost", "Target host", false)
+
+	if err := p.Parse([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if host != "example.com" {
+		t.Errorf("Expected env fallback 'example.com', got %s", host)
+	}
+}
+
+func TestStringVarECLIOverridesEnv(t *testing.T) {
+	os.Setenv("GOARGS_TEST_HOST", "example.com")
+	defer os.Unsetenv("GOARGS_TEST_HOST")
+
+	p := NewParser(&bytes.Buffer{})
+	var host string
+	p.StringVarE(&host, "", "host", "GOARGS_TEST_HOST", "localhost", "Target host", false)
+
+	if err := p.Parse([]string{"--host=cli.example.com"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if host != "cli.example.com" {
+		t.Errorf("Expected CLI value to win, got %s", host)
+	}
+}
+
+func TestIntVarEInvalidEnv(t *testing.T) {
+	os.Setenv("GOARGS_TEST_PORT", "notanumber")
+	defer os.Unsetenv("GOARGS_TEST_PORT")
+
+	p := NewParser(&bytes.Buffer{})
+	var port int
+	p.IntVarE(&port, "", "port", "GOARGS_TEST_PORT", 8080, "Port", false)
+
+	err := p.Parse([]string{})
+	if err == nil || !strings.Contains(err.Error(), "invalid value for flag") {
+		t.Fatalf("Expected invalid value error, got %v", err)
+	}
+}
+
+func TestRequiredFlagMissing(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var name string
+	p.StringVarE(&name, "n", "name", "", "", "User name", true)
+
+	err := p.Parse([]string{})
+	if err == nil {
+		t.Fatal("Expected missing required flag error")
+	}
+	missingErr, ok := err.(*MissingRequiredError)
+	if !ok {
+		t.Fatalf("Expected *MissingRequiredError, got %T", err)
+	}
+	if !strings.Contains(missingErr.Error(), "-n/--name") {
+		t.Errorf("Expected error to name -n/--name, got %s", missingErr.Error())
+	}
+}
+
+func TestRequiredFlagSatisfiedByEnv(t *testing.T) {
+	os.Setenv("GOARGS_TEST_NAME_REQ", "fromenv")
+	defer os.Unsetenv("GOARGS_TEST_NAME_REQ")
+
+	p := NewParser(&bytes.Buffer{})
+	var name string
+	p.StringVarE(&name, "n", "name", "GOARGS_TEST_NAME_REQ", "", "User name", true)
+
+	if err := p.Parse([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "fromenv" {
+		t.Errorf("Expected 'fromenv', got %s", name)
+	}
+}
+
+func TestRequiredFlagSatisfiedByCLI(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var name string
+	p.StringVarE(&name, "n", "name", "", "", "User name", true)
+
+	if err := p.Parse([]string{"-n", "Jane"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "Jane" {
+		t.Errorf("Expected 'Jane', got %s", name)
+	}
+}
+
+func TestUsageShowsEnvAndRequiredMarkers(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewParser(out)
+	var name string
+	p.StringVarE(&name, "n", "name", "APP_NAME", "", "User name", true)
+
+	p.Usage()
+	usage := out.String()
+	if !strings.Contains(usage, "[env: APP_NAME]") {
+		t.Errorf("Expected env marker in usage, got %s", usage)
+	}
+	if !strings.Contains(usage, "[required]") {
+		t.Errorf("Expected required marker in usage, got %s", usage)
+	}
+}
@@ -4,26 +4,33 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"strconv"
+	"os"
 	"strings"
+	"time"
 )
 
 // Parser represents a command-line argument parser.
 type Parser struct {
-	flags      []flagInfo // Stores registered flags
-	positional []string   // Stores positional arguments
-	output     io.Writer  // Where to write usage and errors
-	program    string     // Program name for usage (optional)
+	flags            []flagInfo // Stores registered flags
+	positional       []string   // Stores positional arguments
+	positionalTarget *[]string  // Field bound via a `positional:"true"` struct tag, if any
+	commands         []*Command // Stores registered subcommands
+	matched          *Command   // Subcommand selected by the most recent Parse call
+	output           io.Writer  // Where to write usage and errors
+	program          string     // Program name for usage (optional)
 }
 
 // flagInfo holds metadata about a registered flag.
 type flagInfo struct {
 	shortName    string      // Short flag name (e.g., "v")
 	longName     string      // Long flag name (e.g., "verbose")
-	ptr          interface{} // Pointer to the variable to store the value
-	defaultValue interface{} // Default value of the flag
+	value        Value       // Backing value, responsible for parsing and storage
+	defaultValue interface{} // Default value, kept for Usage rendering; nil if not applicable
 	description  string      // Description for documentation
-	kind         string      // Type of the flag: "string", "int", or "bool"
+	group        string      // Usage grouping label set by RegisterStruct, if any
+	envVar       string      // Environment variable consulted when the flag is absent from argv
+	required     bool        // If true, parseArgs reports a MissingRequiredError when left unset
+	wasSet       bool        // Whether the flag was set via argv or its envVar fallback, for Visit
 }
 
 // NewParser creates and initializes a new Parser instance with an output writer.
@@ -43,139 +50,581 @@ func (p *Parser) SetProgramName(name string) {
 
 // StringVar registers a string flag.
 func (p *Parser) StringVar(ptr *string, shortName, longName string, defaultValue string, description string) {
-	*ptr = defaultValue
-	p.flags = append(p.flags, flagInfo{shortName, longName, ptr, defaultValue, description, "string"})
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newStringValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
 }
 
 // IntVar registers an integer flag.
 func (p *Parser) IntVar(ptr *int, shortName, longName string, defaultValue int, description string) {
-	*ptr = defaultValue
-	p.flags = append(p.flags, flagInfo{shortName, longName, ptr, defaultValue, description, "int"})
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newIntValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
 }
 
 // BoolVar registers a boolean flag.
 func (p *Parser) BoolVar(ptr *bool, shortName, longName string, defaultValue bool, description string) {
-	*ptr = defaultValue
-	p.flags = append(p.flags, flagInfo{shortName, longName, ptr, defaultValue, description, "bool"})
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newBoolValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// StringVarE registers a string flag with an environment-variable fallback
+// and/or required-flag enforcement. If the flag is absent from argv, Parse
+// falls back to os.Getenv(envVar) when envVar is non-empty and the variable
+// holds a non-empty value; a variable set to "" is treated as unset. If the
+// flag is still unset after that and required is true, Parse
+// returns a *MissingRequiredError.
+func (p *Parser) StringVarE(ptr *string, shortName, longName, envVar string, defaultValue string, description string, required bool) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newStringValue(defaultValue, ptr), defaultValue: defaultValue, description: description, envVar: envVar, required: required})
+}
+
+// IntVarE is IntVar's counterpart with environment-variable fallback and
+// required-flag enforcement; see StringVarE.
+func (p *Parser) IntVarE(ptr *int, shortName, longName, envVar string, defaultValue int, description string, required bool) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newIntValue(defaultValue, ptr), defaultValue: defaultValue, description: description, envVar: envVar, required: required})
+}
+
+// BoolVarE is BoolVar's counterpart with environment-variable fallback and
+// required-flag enforcement; see StringVarE.
+func (p *Parser) BoolVarE(ptr *bool, shortName, longName, envVar string, defaultValue bool, description string, required bool) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newBoolValue(defaultValue, ptr), defaultValue: defaultValue, description: description, envVar: envVar, required: required})
+}
+
+// Float64Var registers a float64 flag.
+func (p *Parser) Float64Var(ptr *float64, shortName, longName string, defaultValue float64, description string) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newFloat64Value(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// Int64Var registers an int64 flag.
+func (p *Parser) Int64Var(ptr *int64, shortName, longName string, defaultValue int64, description string) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newInt64Value(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// UintVar registers a uint flag.
+func (p *Parser) UintVar(ptr *uint, shortName, longName string, defaultValue uint, description string) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newUintValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// Uint64Var registers a uint64 flag.
+func (p *Parser) Uint64Var(ptr *uint64, shortName, longName string, defaultValue uint64, description string) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newUint64Value(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// DurationVar registers a time.Duration flag, parsed with time.ParseDuration.
+func (p *Parser) DurationVar(ptr *time.Duration, shortName, longName string, defaultValue time.Duration, description string) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newDurationValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// StringSliceVar registers a repeatable string flag. Each occurrence's raw
+// value is split on commas and appended, so `-t foo -t bar --tag=baz`
+// accumulates into []string{"foo", "bar", "baz"}.
+func (p *Parser) StringSliceVar(ptr *[]string, shortName, longName string, defaultValue []string, description string) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newStringSliceValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// StringArrayVar registers a repeatable string flag, like StringSliceVar,
+// except each occurrence's raw value is kept verbatim rather than split on
+// commas.
+func (p *Parser) StringArrayVar(ptr *[]string, shortName, longName string, defaultValue []string, description string) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newStringArrayValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// IntSliceVar registers a repeatable int flag, comma-splitting each
+// occurrence's raw value.
+func (p *Parser) IntSliceVar(ptr *[]int, shortName, longName string, defaultValue []int, description string) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newIntSliceValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// BoolSliceVar registers a repeatable bool flag, comma-splitting each
+// occurrence's raw value. Like BoolVar, it is settable by bare presence.
+func (p *Parser) BoolSliceVar(ptr *[]bool, shortName, longName string, defaultValue []bool, description string) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newBoolSliceValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// DurationSliceVar registers a repeatable time.Duration flag,
+// comma-splitting each occurrence's raw value.
+func (p *Parser) DurationSliceVar(ptr *[]time.Duration, shortName, longName string, defaultValue []time.Duration, description string) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newDurationSliceValue(defaultValue, ptr), defaultValue: defaultValue, description: description})
+}
+
+// CountVar registers a flag whose backing int increments by one on every
+// occurrence, for verbosity-style flags like `-vvv`.
+func (p *Parser) CountVar(ptr *int, shortName, longName string, description string) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: newCountValue(0, ptr), defaultValue: 0, description: description})
+}
+
+// Var registers a user-defined flag value, for types not covered by the
+// built-in *Var helpers.
+func (p *Parser) Var(v Value, shortName, longName, description string) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: v, description: description})
+}
+
+// FuncVar registers a flag that invokes fn with the raw string argument
+// instead of storing into a backing variable, for repeated or
+// side-effecting flags.
+func (p *Parser) FuncVar(shortName, longName, description string, fn func(string) error) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: funcValue(fn), description: description})
+}
+
+// BoolFuncVar is FuncVar's boolean-flag counterpart: fn is invoked with
+// "true" when the flag is given bare (e.g. -v), or with an explicit value
+// from --flag=value.
+func (p *Parser) BoolFuncVar(shortName, longName, description string, fn func(string) error) {
+	p.flags = append(p.flags, flagInfo{shortName: shortName, longName: longName, value: boolFuncValue(fn), description: description})
+}
+
+// AddCommand registers a named subcommand and returns a handle on which
+// flags can be declared with StringVar/IntVar/BoolVar, the same way as on
+// Parser itself. Once any command is registered, Parse requires the first
+// non-flag argument to name one of them.
+func (p *Parser) AddCommand(name, description string) *Command {
+	cmd := &Command{
+		name:        name,
+		description: description,
+		parser:      p,
+		output:      p.output,
+	}
+	p.commands = append(p.commands, cmd)
+	return cmd
 }
 
 // Parse processes command-line arguments and returns an error if parsing fails.
 // If --help or -h is present, it prints usage and returns a special error.
+//
+// Once AddCommand has been used to register at least one subcommand, Parse
+// switches to command-dispatch mode: global flags are parsed up to the
+// first non-flag token, which is then treated as a command name, and the
+// remaining arguments are routed to that command's own flag set.
 func (p *Parser) Parse(args []string) error {
-	// Check for help flags
-	if contains(args, "--help") || contains(args, "-h") {
+	if len(p.commands) == 0 {
+		return p.parseFlat(args)
+	}
+	return p.parseWithCommands(args)
+}
+
+// parseFlat implements the original, command-free parsing behavior: flags
+// may appear anywhere among positional arguments.
+func (p *Parser) parseFlat(args []string) error {
+	beforeTerminator := args
+	if idx := indexOf(args, "--"); idx != -1 {
+		beforeTerminator = args[:idx]
+	}
+	if contains(beforeTerminator, "--help") || contains(beforeTerminator, "-h") {
+		p.Usage()
+		return ErrHelpRequested
+	}
+
+	positional, err := parseArgs(p.flags, args)
+	if err != nil {
+		return err
+	}
+	p.positional = positional
+	if p.positionalTarget != nil {
+		*p.positionalTarget = p.positional
+	}
+	return nil
+}
+
+// parseWithCommands parses global flags, then dispatches the remaining
+// arguments to the matched subcommand.
+func (p *Parser) parseWithCommands(args []string) error {
+	globalArgs, rest := splitGlobalArgs(p.flags, args)
+
+	beforeTerminator := globalArgs
+	if idx := indexOf(globalArgs, "--"); idx != -1 {
+		beforeTerminator = globalArgs[:idx]
+	}
+	if contains(beforeTerminator, "--help") || contains(beforeTerminator, "-h") {
 		p.Usage()
 		return ErrHelpRequested
 	}
 
-	// Reset positional arguments
-	p.positional = []string{}
-	setFlags := make(map[string]string)
+	positional, err := parseArgs(p.flags, globalArgs)
+	if err != nil {
+		return err
+	}
+	p.positional = positional
+	if p.positionalTarget != nil {
+		*p.positionalTarget = p.positional
+	}
+
+	if len(rest) == 0 {
+		return fmt.Errorf("no command specified; available commands: %s", commandNames(p.commands))
+	}
+
+	name, tail := rest[0], rest[1:]
+
+	if name == "help" {
+		if len(tail) == 0 {
+			p.Usage()
+			return ErrHelpRequested
+		}
+		cmd, ok := findCommand(p.commands, tail[0])
+		if !ok {
+			return fmt.Errorf("unknown command %q; available commands: %s", tail[0], commandNames(p.commands))
+		}
+		return cmd.helpFor(tail[1:])
+	}
+
+	cmd, ok := findCommand(p.commands, name)
+	if !ok {
+		return fmt.Errorf("unknown command %q; available commands: %s", name, commandNames(p.commands))
+	}
+
+	p.matched = cmd
+	return cmd.parse(tail)
+}
+
+// Execute invokes the Run function of the subcommand matched by the most
+// recent call to Parse. It returns an error if no subcommand was matched or
+// the matched subcommand (or the leaf subcommand it nested into) has no Run
+// function set.
+func (p *Parser) Execute() error {
+	if p.matched == nil {
+		return errors.New("no command matched; call Parse before Execute")
+	}
+	return p.matched.execute()
+}
+
+// Args returns the list of positional arguments.
+func (p *Parser) Args() []string {
+	return p.positional
+}
+
+// VisitAll calls fn for every registered flag, in registration order,
+// regardless of whether it was set. This matches stdlib flag.VisitAll and is
+// the basis for tooling like config-file loaders or --print-defaults.
+func (p *Parser) VisitAll(fn func(shortName, longName string, value Value)) {
+	for _, fi := range p.flags {
+		fn(fi.shortName, fi.longName, fi.value)
+	}
+}
+
+// Visit calls fn for every registered flag that was set, either on the
+// command line or via its envVar fallback, in registration order. This
+// matches stdlib flag.Visit.
+func (p *Parser) Visit(fn func(shortName, longName string, value Value)) {
+	for _, fi := range p.flags {
+		if fi.wasSet {
+			fn(fi.shortName, fi.longName, fi.value)
+		}
+	}
+}
+
+// Usage generates and writes the usage documentation.
+func (p *Parser) Usage() {
+	fmt.Fprintf(p.output, "Usage of %s:\n", p.program)
+	writeFlagUsage(p.output, p.flags)
+	if len(p.commands) > 0 {
+		fmt.Fprintln(p.output, "\nAvailable commands:")
+		writeCommandList(p.output, p.commands)
+	}
+}
+
+// writeFlagUsage writes the usage block for a set of flags. Consecutive
+// flags sharing a non-empty group (set by RegisterStruct) are preceded by a
+// "<group>:" header the first time that group is encountered.
+func writeFlagUsage(out io.Writer, flags []flagInfo) {
+	lastGroup := ""
+	for _, fi := range flags {
+		if fi.group != "" && fi.group != lastGroup {
+			fmt.Fprintf(out, "\n%s:\n", fi.group)
+			lastGroup = fi.group
+		}
+
+		header := formatFlagNames(fi.shortName, fi.longName)
+		if !fi.value.IsBoolFlag() {
+			if typeLabel := valueTypeName(fi.value); typeLabel != "" {
+				header += " " + typeLabel
+			}
+		}
+
+		line := fi.description
+		if defaultText := formatDefault(fi.defaultValue); defaultText != "" {
+			line = fmt.Sprintf("%s (default %s)", line, defaultText)
+		}
+		if fi.envVar != "" {
+			line = fmt.Sprintf("%s [env: %s]", line, fi.envVar)
+		}
+		if fi.required {
+			line = fmt.Sprintf("%s [required]", line)
+		}
+		fmt.Fprintf(out, "  %s\n        %s\n", header, line)
+	}
+}
+
+// formatFlagNames renders a flag's short/long names the way Usage does.
+func formatFlagNames(shortName, longName string) string {
+	switch {
+	case shortName != "" && longName != "":
+		return fmt.Sprintf("-%s, --%s", shortName, longName)
+	case shortName != "":
+		return fmt.Sprintf("-%s", shortName)
+	case longName != "":
+		return fmt.Sprintf("--%s", longName)
+	default:
+		return ""
+	}
+}
+
+// formatDefault renders a flag's default value for Usage, or "" if none
+// applies (e.g. FuncVar/BoolFuncVar flags).
+func formatDefault(def interface{}) string {
+	switch v := def.(type) {
+	case nil:
+		return ""
+	case string:
+		return fmt.Sprintf("%q", v)
+	case int:
+		return fmt.Sprintf("%d", v)
+	case bool:
+		return fmt.Sprintf("%t", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// writeCommandList writes an aligned "name  description" block for a set of
+// subcommands.
+func writeCommandList(out io.Writer, commands []*Command) {
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-12s %s\n", cmd.name, cmd.description)
+	}
+}
+
+// hasFlag checks if a flag is registered.
+func (p *Parser) hasFlag(name string) bool {
+	return hasFlagIn(p.flags, name)
+}
+
+// needsValue checks if a flag requires a value.
+func (p *Parser) needsValue(name string) bool {
+	return needsValueIn(p.flags, name)
+}
+
+// flagOccurrence records one (name, raw value) pair observed while
+// tokenizing argv, in the order it was seen. Preserving order, rather than
+// collapsing into a map, is what lets repeatable flags (slices, CountVar)
+// accumulate across occurrences instead of only keeping the last one.
+type flagOccurrence struct {
+	name string
+	val  string
+}
+
+// parseArgs scans args for registered flags, applies their values in
+// argv order, and returns the residual positional arguments. Flags may
+// appear anywhere among positionals, mirroring stdlib flag's lenient
+// tokenizing.
+func parseArgs(flags []flagInfo, args []string) ([]string, error) {
+	positional := []string{}
+	var occurrences []flagOccurrence
 
-	// Parse arguments
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
+		if arg == "--" {
+			// POSIX end-of-options marker: everything after it is positional,
+			// even tokens that look like flags.
+			positional = append(positional, args[i+1:]...)
+			break
+		}
 		if strings.HasPrefix(arg, "--") {
 			if strings.Contains(arg, "=") {
 				parts := strings.SplitN(arg[2:], "=", 2)
-				setFlags[parts[0]] = parts[1]
+				occurrences = append(occurrences, flagOccurrence{parts[0], parts[1]})
 			} else {
 				flag := arg[2:]
-				if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") && p.needsValue(flag) {
-					setFlags[flag] = args[i+1]
+				if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") && needsValueIn(flags, flag) {
+					occurrences = append(occurrences, flagOccurrence{flag, args[i+1]})
 					i++
 				} else {
-					setFlags[flag] = "true"
+					occurrences = append(occurrences, flagOccurrence{flag, "true"})
 				}
 			}
 		} else if strings.HasPrefix(arg, "-") && arg != "-" {
-			flag := arg[1:]
 			if strings.Contains(arg, "=") {
 				parts := strings.SplitN(arg[1:], "=", 2)
-				setFlags[parts[0]] = parts[1]
-			} else if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") && p.needsValue(flag) {
-				setFlags[flag] = args[i+1]
-				i++
+				occurrences = append(occurrences, flagOccurrence{parts[0], parts[1]})
 			} else {
-				setFlags[flag] = "true"
+				occs, consumed := decomposeShortFlags(flags, arg[1:], args, i)
+				occurrences = append(occurrences, occs...)
+				i += consumed
 			}
 		} else {
-			p.positional = append(p.positional, arg)
-		}
-	}
-
-	// Set flag values, processing in reverse to ensure last registration wins
-	for i := len(p.flags) - 1; i >= 0; i-- {
-		fi := p.flags[i]
-		for _, name := range []string{fi.shortName, fi.longName} {
-			if val, ok := setFlags[name]; ok {
-				switch fi.kind {
-				case "string":
-					*fi.ptr.(*string) = val
-				case "int":
-					i, err := strconv.Atoi(val)
-					if err != nil {
-						return fmt.Errorf("invalid value for flag -%s/--%s: %s", fi.shortName, fi.longName, val)
-					}
-					*fi.ptr.(*int) = i
-				case "bool":
-					if val == "true" {
-						*fi.ptr.(*bool) = true
-					} else if val == "false" {
-						*fi.ptr.(*bool) = false
-					} else {
-						return fmt.Errorf("invalid value for bool flag -%s/--%s: %s", fi.shortName, fi.longName, val)
-					}
+			positional = append(positional, arg)
+		}
+	}
+
+	// Resolve each occurrence's name to the last-registered flagInfo sharing
+	// it (matching the old "last registration wins" rule for duplicate flag
+	// names) and apply values in argv order, so repeatable flags accumulate.
+	lookup := make(map[string]*flagInfo, len(flags)*2)
+	for i := range flags {
+		fi := &flags[i]
+		if fi.shortName != "" {
+			lookup[fi.shortName] = fi
+		}
+		if fi.longName != "" {
+			lookup[fi.longName] = fi
+		}
+	}
+
+	set := make(map[*flagInfo]bool, len(occurrences))
+	for _, occ := range occurrences {
+		fi, ok := lookup[occ.name]
+		if !ok {
+			return nil, fmt.Errorf("unknown flag: %s", occ.name)
+		}
+		if err := setFlagValue(*fi, occ.val); err != nil {
+			return nil, err
+		}
+		fi.wasSet = true
+		set[fi] = true
+	}
+
+	if err := resolveEnvAndRequired(flags, set); err != nil {
+		return nil, err
+	}
+
+	return positional, nil
+}
+
+// resolveEnvAndRequired runs after argv has been applied. For any flag not
+// set on the command line but carrying a non-empty envVar, it looks up
+// os.Getenv(envVar) and, if non-empty, feeds it through the same Set path as
+// a CLI value. Flags still unset after that are collected; if any of them is
+// required, parseArgs fails with a *MissingRequiredError.
+func resolveEnvAndRequired(flags []flagInfo, set map[*flagInfo]bool) error {
+	var missing []string
+	for i := range flags {
+		fi := &flags[i]
+		if set[fi] {
+			continue
+		}
+		if fi.envVar != "" {
+			if envVal := os.Getenv(fi.envVar); envVal != "" {
+				if err := setFlagValue(*fi, envVal); err != nil {
+					return err
 				}
-				// Remove the flag from setFlags to prevent earlier duplicates from being set
-				delete(setFlags, name)
+				fi.wasSet = true
+				continue
 			}
 		}
+		if fi.required {
+			missing = append(missing, flagLabel(fi.shortName, fi.longName))
+		}
 	}
+	if len(missing) > 0 {
+		return &MissingRequiredError{Flags: missing}
+	}
+	return nil
+}
 
-	// Check for unknown flags
-	for flag := range setFlags {
-		if !p.hasFlag(flag) {
-			return fmt.Errorf("unknown flag: %s", flag)
+// decomposeShortFlags expands a single-dash token's body (the part after
+// the leading "-") into one or more flag occurrences, POSIX-getopt style:
+// bool-like flags may be stacked (-xvf == -x -v -f), and the first
+// non-bool-like flag encountered consumes the remainder of the token (or,
+// if none is left, the next argv entry) as its value. It returns how many
+// extra argv entries beyond body's own were consumed (0 or 1).
+//
+// A token that exactly names a single registered flag is handled directly,
+// without attempting decomposition, so single-character flag names keep
+// behaving exactly as before.
+func decomposeShortFlags(flags []flagInfo, body string, args []string, i int) (occurrences []flagOccurrence, consumed int) {
+	if len(body) <= 1 || hasFlagIn(flags, body) {
+		if needsValueIn(flags, body) && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			return []flagOccurrence{{body, args[i+1]}}, 1
 		}
+		return []flagOccurrence{{body, "true"}}, 0
 	}
 
-	return nil
+	for j := 0; j < len(body); j++ {
+		name := string(body[j])
+		if !hasFlagIn(flags, name) {
+			// Not a recognized stack of short flags; fall back to treating
+			// the whole token as a single (likely unknown) flag name.
+			return []flagOccurrence{{body, "true"}}, 0
+		}
+
+		if !needsValueIn(flags, name) {
+			occurrences = append(occurrences, flagOccurrence{name, "true"})
+			continue
+		}
+
+		if rest := body[j+1:]; rest != "" {
+			occurrences = append(occurrences, flagOccurrence{name, rest})
+			return occurrences, 0
+		}
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			occurrences = append(occurrences, flagOccurrence{name, args[i+1]})
+			return occurrences, 1
+		}
+		occurrences = append(occurrences, flagOccurrence{name, "true"})
+		return occurrences, 0
+	}
+
+	return occurrences, 0
 }
 
-// Args returns the list of positional arguments.
-func (p *Parser) Args() []string {
-	return p.positional
+// setFlagValue hands val to fi's backing Value, wrapping any parse failure
+// in an error that names the offending flag.
+func setFlagValue(fi flagInfo, val string) error {
+	if err := fi.value.Set(val); err != nil {
+		if fi.value.IsBoolFlag() {
+			return fmt.Errorf("invalid value for bool flag -%s/--%s: %s", fi.shortName, fi.longName, val)
+		}
+		return fmt.Errorf("invalid value for flag -%s/--%s: %s", fi.shortName, fi.longName, val)
+	}
+	return nil
 }
 
-// Usage generates and writes the usage documentation.
-func (p *Parser) Usage() {
-	fmt.Fprintf(p.output, "Usage of %s:\n", p.program)
-	for _, fi := range p.flags {
-		var flagNames string
-		switch {
-		case fi.shortName != "" && fi.longName != "":
-			flagNames = fmt.Sprintf("-%s, --%s", fi.shortName, fi.longName)
-		case fi.shortName != "":
-			flagNames = fmt.Sprintf("-%s", fi.shortName)
-		case fi.longName != "":
-			flagNames = fmt.Sprintf("--%s", fi.longName)
+// splitGlobalArgs splits args into the leading run of flag tokens (parsed
+// against flags) and the remainder, which starts at the first positional
+// token. It is used to find the command name once global flags have been
+// consumed.
+func splitGlobalArgs(flags []flagInfo, args []string) (globalArgs, rest []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return args[:i], args[i+1:]
+		}
+		if arg == "-" || !strings.HasPrefix(arg, "-") {
+			return args[:i], args[i:]
 		}
+		globalArgs = append(globalArgs, arg)
 
-		switch fi.kind {
-		case "string":
-			fmt.Fprintf(p.output, "  %s string\n        %s (default %q)\n", flagNames, fi.description, fi.defaultValue)
-		case "int":
-			fmt.Fprintf(p.output, "  %s int\n        %s (default %d)\n", flagNames, fi.description, fi.defaultValue)
-		case "bool":
-			fmt.Fprintf(p.output, "  %s\n        %s (default %t)\n", flagNames, fi.description, fi.defaultValue)
+		if !strings.Contains(arg, "=") {
+			name := strings.TrimLeft(arg, "-")
+			if needsValueIn(flags, name) && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				i++
+				globalArgs = append(globalArgs, args[i])
+			}
 		}
 	}
+	return args, nil
 }
 
-// hasFlag checks if a flag is registered.
-func (p *Parser) hasFlag(name string) bool {
-	for _, fi := range p.flags {
+// MissingRequiredError reports that one or more required flags were left
+// unset after command-line and environment-variable resolution, whether
+// marked required via a RegisterStruct `required:"true"` tag or a ...VarE
+// overload's required parameter.
+type MissingRequiredError struct {
+	Flags []string // Flag labels, e.g. "-n/--name"
+}
+
+func (e *MissingRequiredError) Error() string {
+	return fmt.Sprintf("missing required flag(s): %s", strings.Join(e.Flags, ", "))
+}
+
+// flagLabel renders a flag's short/long names the way error messages do.
+func flagLabel(shortName, longName string) string {
+	switch {
+	case shortName != "" && longName != "":
+		return fmt.Sprintf("-%s/--%s", shortName, longName)
+	case shortName != "":
+		return fmt.Sprintf("-%s", shortName)
+	default:
+		return fmt.Sprintf("--%s", longName)
+	}
+}
+
+// hasFlagIn checks if a flag is registered in flags.
+func hasFlagIn(flags []flagInfo, name string) bool {
+	for _, fi := range flags {
 		if fi.shortName == name || fi.longName == name {
 			return true
 		}
@@ -183,10 +632,10 @@ func (p *Parser) hasFlag(name string) bool {
 	return false
 }
 
-// needsValue checks if a flag requires a value.
-func (p *Parser) needsValue(name string) bool {
-	for _, fi := range p.flags {
-		if (fi.shortName == name || fi.longName == name) && fi.kind != "bool" {
+// needsValueIn checks if a flag in flags requires a value.
+func needsValueIn(flags []flagInfo, name string) bool {
+	for _, fi := range flags {
+		if (fi.shortName == name || fi.longName == name) && !fi.value.IsBoolFlag() {
 			return true
 		}
 	}
@@ -203,5 +652,16 @@ func contains(slice []string, item string) bool {
 	return false
 }
 
+// indexOf returns the index of the first occurrence of item in slice, or -1
+// if it is not present.
+func indexOf(slice []string, item string) int {
+	for i, s := range slice {
+		if s == item {
+			return i
+		}
+	}
+	return -1
+}
+
 // ErrHelpRequested is returned when --help or -h is encountered.
 var ErrHelpRequested = errors.New("help requested")
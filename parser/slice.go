@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stringSliceValue is a Value backed by a *[]string. Each occurrence's raw
+// argument is split on commas, so `-t foo -t bar --tag=baz` and `-t
+// foo,bar,baz` both yield []string{"foo", "bar", "baz"}.
+type stringSliceValue struct{ p *[]string }
+
+func newStringSliceValue(def []string, p *[]string) *stringSliceValue {
+	*p = append([]string{}, def...)
+	return &stringSliceValue{p}
+}
+
+func (s *stringSliceValue) Set(val string) error {
+	*s.p = append(*s.p, strings.Split(val, ",")...)
+	return nil
+}
+func (s *stringSliceValue) String() string   { return strings.Join(*s.p, ",") }
+func (s *stringSliceValue) IsBoolFlag() bool { return false }
+func (s *stringSliceValue) typeName() string { return "strings" }
+
+// stringArrayValue is a Value backed by a *[]string. Unlike
+// stringSliceValue, each occurrence's raw argument is appended verbatim,
+// without splitting on commas.
+type stringArrayValue struct{ p *[]string }
+
+func newStringArrayValue(def []string, p *[]string) *stringArrayValue {
+	*p = append([]string{}, def...)
+	return &stringArrayValue{p}
+}
+
+func (s *stringArrayValue) Set(val string) error {
+	*s.p = append(*s.p, val)
+	return nil
+}
+func (s *stringArrayValue) String() string   { return strings.Join(*s.p, ",") }
+func (s *stringArrayValue) IsBoolFlag() bool { return false }
+func (s *stringArrayValue) typeName() string { return "strings" }
+
+// intSliceValue is a Value backed by a *[]int, splitting each occurrence's
+// raw argument on commas.
+type intSliceValue struct{ p *[]int }
+
+func newIntSliceValue(def []int, p *[]int) *intSliceValue {
+	*p = append([]int{}, def...)
+	return &intSliceValue{p}
+}
+
+func (s *intSliceValue) Set(val string) error {
+	for _, part := range strings.Split(val, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return err
+		}
+		*s.p = append(*s.p, n)
+	}
+	return nil
+}
+func (s *intSliceValue) String() string {
+	parts := make([]string, len(*s.p))
+	for i, n := range *s.p {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ",")
+}
+func (s *intSliceValue) IsBoolFlag() bool { return false }
+func (s *intSliceValue) typeName() string { return "ints" }
+
+// boolSliceValue is a Value backed by a *[]bool, splitting each occurrence's
+// raw argument on commas. It is bare-presence settable like a plain bool
+// flag, appending "true" for each bare occurrence.
+type boolSliceValue struct{ p *[]bool }
+
+func newBoolSliceValue(def []bool, p *[]bool) *boolSliceValue {
+	*p = append([]bool{}, def...)
+	return &boolSliceValue{p}
+}
+
+func (s *boolSliceValue) Set(val string) error {
+	for _, part := range strings.Split(val, ",") {
+		b, err := strconv.ParseBool(strings.TrimSpace(part))
+		if err != nil {
+			return err
+		}
+		*s.p = append(*s.p, b)
+	}
+	return nil
+}
+func (s *boolSliceValue) String() string {
+	parts := make([]string, len(*s.p))
+	for i, b := range *s.p {
+		parts[i] = strconv.FormatBool(b)
+	}
+	return strings.Join(parts, ",")
+}
+func (s *boolSliceValue) IsBoolFlag() bool { return true }
+func (s *boolSliceValue) typeName() string { return "bools" }
+
+// durationSliceValue is a Value backed by a *[]time.Duration, splitting each
+// occurrence's raw argument on commas.
+type durationSliceValue struct{ p *[]time.Duration }
+
+func newDurationSliceValue(def []time.Duration, p *[]time.Duration) *durationSliceValue {
+	*p = append([]time.Duration{}, def...)
+	return &durationSliceValue{p}
+}
+
+func (s *durationSliceValue) Set(val string) error {
+	for _, part := range strings.Split(val, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			return err
+		}
+		*s.p = append(*s.p, d)
+	}
+	return nil
+}
+func (s *durationSliceValue) String() string {
+	parts := make([]string, len(*s.p))
+	for i, d := range *s.p {
+		parts[i] = d.String()
+	}
+	return strings.Join(parts, ",")
+}
+func (s *durationSliceValue) IsBoolFlag() bool { return false }
+func (s *durationSliceValue) typeName() string { return "durations" }
+
+// countValue is a Value backed by a *int that increments by one on every
+// occurrence, for flags like `-vvv` meaning "verbosity level 3".
+type countValue int
+
+func newCountValue(def int, p *int) *countValue {
+	*p = def
+	return (*countValue)(p)
+}
+
+func (c *countValue) Set(string) error { *c++; return nil }
+func (c *countValue) String() string   { return strconv.Itoa(int(*c)) }
+func (c *countValue) IsBoolFlag() bool { return true }
+func (c *countValue) typeName() string { return "count" }
@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStringSliceVarAccumulates(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var tags []string
+	p.StringSliceVar(&tags, "t", "tag", nil, "Add a tag")
+
+	err := p.Parse([]string{"-t", "foo", "-t", "bar", "--tag=baz"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(tags, []string{"foo", "bar", "baz"}) {
+		t.Errorf("Expected [foo bar baz], got %v", tags)
+	}
+}
+
+func TestStringSliceVarSplitsCommas(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var tags []string
+	p.StringSliceVar(&tags, "t", "tag", nil, "Add a tag")
+
+	if err := p.Parse([]string{"--tag=a,b,c"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(tags, []string{"a", "b", "c"}) {
+		t.Errorf("Expected [a b c], got %v", tags)
+	}
+}
+
+func TestStringArrayVarKeepsCommasVerbatim(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var items []string
+	p.StringArrayVar(&items, "i", "item", nil, "Add an item")
+
+	if err := p.Parse([]string{"--item=a,b,c", "-i", "d"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(items, []string{"a,b,c", "d"}) {
+		t.Errorf("Expected [a,b,c d], got %v", items)
+	}
+}
+
+func TestIntSliceVar(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var nums []int
+	p.IntSliceVar(&nums, "n", "num", nil, "Add a number")
+
+	if err := p.Parse([]string{"--num=1,2", "-n", "3"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(nums, []int{1, 2, 3}) {
+		t.Errorf("Expected [1 2 3], got %v", nums)
+	}
+}
+
+func TestDurationSliceVar(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var waits []time.Duration
+	p.DurationSliceVar(&waits, "w", "wait", nil, "Add a wait")
+
+	if err := p.Parse([]string{"--wait=1s,2s"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(waits, []time.Duration{time.Second, 2 * time.Second}) {
+		t.Errorf("Expected [1s 2s], got %v", waits)
+	}
+}
+
+func TestCountVarStacked(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var verbosity int
+	p.CountVar(&verbosity, "v", "verbose", "Increase verbosity")
+
+	if err := p.Parse([]string{"-vvv"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if verbosity != 3 {
+		t.Errorf("Expected verbosity 3, got %d", verbosity)
+	}
+}
+
+func TestCountVarRepeated(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var verbosity int
+	p.CountVar(&verbosity, "v", "verbose", "Increase verbosity")
+
+	if err := p.Parse([]string{"-v", "-v"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if verbosity != 2 {
+		t.Errorf("Expected verbosity 2, got %d", verbosity)
+	}
+}
+
+func TestStackedShortFlagsWithValue(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var extract, verbose bool
+	var file string
+	p.BoolVar(&extract, "x", "extract", false, "Extract")
+	p.BoolVar(&verbose, "v", "verbose", false, "Verbose")
+	p.StringVar(&file, "f", "file", "", "Archive file")
+
+	err := p.Parse([]string{"-xvf", "archive.tar"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !extract || !verbose {
+		t.Errorf("Expected extract and verbose true, got %t/%t", extract, verbose)
+	}
+	if file != "archive.tar" {
+		t.Errorf("Expected file 'archive.tar', got %s", file)
+	}
+}
+
+func TestShortFlagAttachedValue(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var output string
+	p.StringVar(&output, "o", "output", "", "Output file")
+
+	if err := p.Parse([]string{"-ovalue.txt"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output != "value.txt" {
+		t.Errorf("Expected 'value.txt', got %s", output)
+	}
+}
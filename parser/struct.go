@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// RegisterStruct walks v, a pointer to a struct, by reflection and
+// registers a flag for each exported field carrying a `short` and/or `long`
+// tag, mirroring jessevdk/go-flags. A field can instead be bound to the
+// parser's positional arguments via `positional:"true"`.
+//
+// Supported tags:
+//
+//	short:"n"          single-character flag name
+//	long:"name"        multi-character flag name
+//	default:"anon"     default value, parsed according to the field's type
+//	desc:"user name"   flag description
+//	env:"APP_NAME"     environment variable consulted at Parse time if the flag is never set
+//	required:"true"    Parse returns an error if the flag is never set
+//	positional:"true"  bind trailing positionals into this []string field
+//	group:"name"       label prefix for an embedded struct's flags in Usage
+//
+// Anonymous (embedded) struct fields are recursed into, scoped by their own
+// group tag if present, or by the enclosing group otherwise.
+func (p *Parser) RegisterStruct(v interface{}) error {
+	return registerStruct(p, v, "")
+}
+
+func registerStruct(p *Parser, v interface{}, group string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("RegisterStruct requires a pointer to a struct, got %T", v)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fieldVal := rv.Field(i)
+		if !fieldVal.CanSet() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			childGroup := field.Tag.Get("group")
+			if childGroup == "" {
+				childGroup = group
+			}
+			if err := registerStruct(p, fieldVal.Addr().Interface(), childGroup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if field.Tag.Get("positional") == "true" {
+			target, ok := fieldVal.Addr().Interface().(*[]string)
+			if !ok {
+				return fmt.Errorf("field %s: positional fields must be []string", field.Name)
+			}
+			p.positionalTarget = target
+			continue
+		}
+
+		short := field.Tag.Get("short")
+		long := field.Tag.Get("long")
+		if short == "" && long == "" {
+			continue
+		}
+
+		desc := field.Tag.Get("desc")
+		required := field.Tag.Get("required") == "true"
+		envName := field.Tag.Get("env")
+
+		if err := registerStructField(p, field, fieldVal, short, long, desc, field.Tag.Get("default"), envName, required, group); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// registerStructField registers the flag for a single struct field once its
+// tags have been extracted by registerStruct. env and required are routed
+// through the same envVar/required mechanism as StringVarE and friends, so
+// Parse resolves the environment variable and enforces required-ness, and
+// Usage annotates the flag with "[env: ...]"/"[required]" like any other
+// flag registered that way.
+func registerStructField(p *Parser, field reflect.StructField, fieldVal reflect.Value, short, long, desc, defaultTag, envName string, required bool, group string) error {
+	switch field.Type.Kind() {
+	case reflect.String:
+		ptr := fieldVal.Addr().Interface().(*string)
+		p.StringVarE(ptr, short, long, envName, defaultTag, desc, required)
+		p.setGroup(short, long, group)
+
+	case reflect.Int:
+		def, err := parseIntTag(field.Name, "default", defaultTag, 0)
+		if err != nil {
+			return err
+		}
+		ptr := fieldVal.Addr().Interface().(*int)
+		p.IntVarE(ptr, short, long, envName, def, desc, required)
+		p.setGroup(short, long, group)
+
+	case reflect.Bool:
+		def, err := parseBoolTag(field.Name, "default", defaultTag, false)
+		if err != nil {
+			return err
+		}
+		ptr := fieldVal.Addr().Interface().(*bool)
+		p.BoolVarE(ptr, short, long, envName, def, desc, required)
+		p.setGroup(short, long, group)
+
+	default:
+		return fmt.Errorf("field %s: unsupported field kind %s", field.Name, field.Type.Kind())
+	}
+
+	return nil
+}
+
+// parseIntTag parses an int-typed tag value, returning fallback if raw is empty.
+func parseIntTag(fieldName, tagName, raw string, fallback int) (int, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("field %s: invalid %s %q: %w", fieldName, tagName, raw, err)
+	}
+	return n, nil
+}
+
+// parseBoolTag parses a bool-typed tag value, returning fallback if raw is empty.
+func parseBoolTag(fieldName, tagName, raw string, fallback bool) (bool, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("field %s: invalid %s %q: %w", fieldName, tagName, raw, err)
+	}
+	return b, nil
+}
+
+// setGroup assigns a Usage grouping label to the flag most recently
+// registered under the given names.
+func (p *Parser) setGroup(short, long, group string) {
+	if group == "" {
+		return
+	}
+	for i := len(p.flags) - 1; i >= 0; i-- {
+		if p.flags[i].shortName == short && p.flags[i].longName == long {
+			p.flags[i].group = group
+			return
+		}
+	}
+}
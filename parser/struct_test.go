@@ -0,0 +1,231 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegisterStructBasic(t *testing.T) {
+	type Opts struct {
+		Name    string `short:"n" long:"name" default:"anon" desc:"user name"`
+		Verbose bool   `short:"v" long:"verbose" desc:"verbose output"`
+		Age     int    `long:"age" desc:"user age"`
+	}
+
+	var opts Opts
+	out := &bytes.Buffer{}
+	p := NewParser(out)
+	if err := p.RegisterStruct(&opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if opts.Name != "anon" {
+		t.Errorf("Expected default name 'anon', got %s", opts.Name)
+	}
+
+	if err := p.Parse([]string{"-n", "Jane", "--age=30", "-v"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.Name != "Jane" || opts.Age != 30 || !opts.Verbose {
+		t.Errorf("Expected Jane/30/true, got %s/%d/%t", opts.Name, opts.Age, opts.Verbose)
+	}
+}
+
+func TestRegisterStructEnvFallback(t *testing.T) {
+	type Opts struct {
+		Name string `long:"name" default:"anon" env:"GOARGS_TEST_NAME"`
+	}
+
+	os.Setenv("GOARGS_TEST_NAME", "fromenv")
+	defer os.Unsetenv("GOARGS_TEST_NAME")
+
+	var opts Opts
+	p := NewParser(&bytes.Buffer{})
+	if err := p.RegisterStruct(&opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.Name != "anon" {
+		t.Errorf("Expected tag default 'anon' before Parse, got %s", opts.Name)
+	}
+
+	if err := p.Parse([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.Name != "fromenv" {
+		t.Errorf("Expected env value to be resolved by Parse, got %s", opts.Name)
+	}
+}
+
+func TestRegisterStructEnvSatisfiesRequired(t *testing.T) {
+	type Opts struct {
+		Name string `long:"name" env:"GOARGS_TEST_NAME_REQUIRED" required:"true"`
+	}
+
+	os.Setenv("GOARGS_TEST_NAME_REQUIRED", "fromenv")
+	defer os.Unsetenv("GOARGS_TEST_NAME_REQUIRED")
+
+	var opts Opts
+	p := NewParser(&bytes.Buffer{})
+	if err := p.RegisterStruct(&opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := p.Parse([]string{}); err != nil {
+		t.Fatalf("Expected env value to satisfy required, got %v", err)
+	}
+	if opts.Name != "fromenv" {
+		t.Errorf("Expected Name 'fromenv', got %s", opts.Name)
+	}
+
+	os.Unsetenv("GOARGS_TEST_NAME_REQUIRED")
+	opts = Opts{}
+	p = NewParser(&bytes.Buffer{})
+	if err := p.RegisterStruct(&opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	err := p.Parse([]string{})
+	if err == nil || !strings.Contains(err.Error(), "missing required flag") {
+		t.Fatalf("Expected missing required flag error when env unset, got %v", err)
+	}
+}
+
+func TestRegisterStructRequired(t *testing.T) {
+	type Opts struct {
+		Name string `long:"name" required:"true"`
+	}
+
+	var opts Opts
+	p := NewParser(&bytes.Buffer{})
+	if err := p.RegisterStruct(&opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err := p.Parse([]string{})
+	if err == nil || !strings.Contains(err.Error(), "missing required flag") {
+		t.Fatalf("Expected missing required flag error, got %v", err)
+	}
+
+	opts = Opts{}
+	p = NewParser(&bytes.Buffer{})
+	if err := p.RegisterStruct(&opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := p.Parse([]string{"--name=set"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestRegisterStructMultipleRequiredFields(t *testing.T) {
+	type Opts struct {
+		A string `long:"a" required:"true"`
+		B string `long:"b" required:"true"`
+	}
+
+	var opts Opts
+	p := NewParser(&bytes.Buffer{})
+	if err := p.RegisterStruct(&opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := p.Parse([]string{"--a=1", "--b=2"}); err != nil {
+		t.Fatalf("Expected both required flags set to satisfy required, got %v", err)
+	}
+	if opts.A != "1" || opts.B != "2" {
+		t.Errorf("Expected A=1 B=2, got A=%s B=%s", opts.A, opts.B)
+	}
+}
+
+func TestRegisterStructRequiredSetToDefaultValue(t *testing.T) {
+	type Opts struct {
+		Count int `short:"c" long:"count" default:"5" required:"true"`
+	}
+
+	var opts Opts
+	p := NewParser(&bytes.Buffer{})
+	if err := p.RegisterStruct(&opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := p.Parse([]string{"-c", "5"}); err != nil {
+		t.Fatalf("Expected required flag explicitly set to its default value to satisfy required, got %v", err)
+	}
+}
+
+func TestRegisterStructRequiredBoolSetToFalse(t *testing.T) {
+	type Opts struct {
+		Enabled bool `long:"enabled" required:"true"`
+	}
+
+	var opts Opts
+	p := NewParser(&bytes.Buffer{})
+	if err := p.RegisterStruct(&opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := p.Parse([]string{"--enabled=false"}); err != nil {
+		t.Fatalf("Expected required bool flag explicitly set to false to satisfy required, got %v", err)
+	}
+}
+
+func TestRegisterStructPositional(t *testing.T) {
+	type Opts struct {
+		Verbose bool     `short:"v" long:"verbose"`
+		Files   []string `positional:"true"`
+	}
+
+	var opts Opts
+	p := NewParser(&bytes.Buffer{})
+	if err := p.RegisterStruct(&opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := p.Parse([]string{"-v", "a.txt", "b.txt"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(opts.Files, []string{"a.txt", "b.txt"}) {
+		t.Errorf("Expected [a.txt b.txt], got %v", opts.Files)
+	}
+}
+
+func TestRegisterStructEmbedded(t *testing.T) {
+	type Common struct {
+		Verbose bool `short:"v" long:"verbose" desc:"verbose output"`
+	}
+	type Opts struct {
+		Common `group:"Common options"`
+		Name   string `long:"name" desc:"user name"`
+	}
+
+	var opts Opts
+	out := &bytes.Buffer{}
+	p := NewParser(out)
+	if err := p.RegisterStruct(&opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := p.Parse([]string{"-v", "--name=x"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !opts.Verbose || opts.Name != "x" {
+		t.Errorf("Expected verbose=true name=x, got %t/%s", opts.Verbose, opts.Name)
+	}
+
+	p.Usage()
+	if !strings.Contains(out.String(), "Common options:") {
+		t.Errorf("Expected group header in usage, got %s", out.String())
+	}
+}
+
+func TestRegisterStructRejectsNonPointer(t *testing.T) {
+	type Opts struct {
+		Name string `long:"name"`
+	}
+
+	p := NewParser(&bytes.Buffer{})
+	err := p.RegisterStruct(Opts{})
+	if err == nil {
+		t.Fatal("Expected error for non-pointer argument")
+	}
+}
@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"strconv"
+	"time"
+)
+
+// Value is implemented by any type that can be set from a command-line
+// argument and rendered back to one, mirroring stdlib flag.Value. IsBoolFlag
+// reports whether the flag should be settable by bare presence (e.g. -v)
+// rather than requiring an explicit value.
+type Value interface {
+	String() string
+	Set(string) error
+	IsBoolFlag() bool
+}
+
+// typeNamer is implemented by the built-in Value types so Usage can render a
+// type label for a flag without a hardcoded kind switch.
+type typeNamer interface {
+	typeName() string
+}
+
+// stringValue is a Value backed by a *string.
+type stringValue string
+
+func newStringValue(val string, p *string) *stringValue {
+	*p = val
+	return (*stringValue)(p)
+}
+
+func (s *stringValue) Set(val string) error { *s = stringValue(val); return nil }
+func (s *stringValue) String() string       { return string(*s) }
+func (s *stringValue) IsBoolFlag() bool     { return false }
+func (s *stringValue) typeName() string     { return "string" }
+
+// intValue is a Value backed by a *int.
+type intValue int
+
+func newIntValue(val int, p *int) *intValue {
+	*p = val
+	return (*intValue)(p)
+}
+
+func (i *intValue) Set(val string) error {
+	v, err := strconv.ParseInt(val, 0, strconv.IntSize)
+	if err != nil {
+		return err
+	}
+	*i = intValue(v)
+	return nil
+}
+func (i *intValue) String() string   { return strconv.Itoa(int(*i)) }
+func (i *intValue) IsBoolFlag() bool { return false }
+func (i *intValue) typeName() string { return "int" }
+
+// boolValue is a Value backed by a *bool.
+type boolValue bool
+
+func newBoolValue(val bool, p *bool) *boolValue {
+	*p = val
+	return (*boolValue)(p)
+}
+
+func (b *boolValue) Set(val string) error {
+	v, err := strconv.ParseBool(val)
+	if err != nil {
+		return err
+	}
+	*b = boolValue(v)
+	return nil
+}
+func (b *boolValue) String() string   { return strconv.FormatBool(bool(*b)) }
+func (b *boolValue) IsBoolFlag() bool { return true }
+func (b *boolValue) typeName() string { return "bool" }
+
+// float64Value is a Value backed by a *float64.
+type float64Value float64
+
+func newFloat64Value(val float64, p *float64) *float64Value {
+	*p = val
+	return (*float64Value)(p)
+}
+
+func (f *float64Value) Set(val string) error {
+	v, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return err
+	}
+	*f = float64Value(v)
+	return nil
+}
+func (f *float64Value) String() string   { return strconv.FormatFloat(float64(*f), 'g', -1, 64) }
+func (f *float64Value) IsBoolFlag() bool { return false }
+func (f *float64Value) typeName() string { return "float64" }
+
+// int64Value is a Value backed by a *int64.
+type int64Value int64
+
+func newInt64Value(val int64, p *int64) *int64Value {
+	*p = val
+	return (*int64Value)(p)
+}
+
+func (i *int64Value) Set(val string) error {
+	v, err := strconv.ParseInt(val, 0, 64)
+	if err != nil {
+		return err
+	}
+	*i = int64Value(v)
+	return nil
+}
+func (i *int64Value) String() string   { return strconv.FormatInt(int64(*i), 10) }
+func (i *int64Value) IsBoolFlag() bool { return false }
+func (i *int64Value) typeName() string { return "int64" }
+
+// uintValue is a Value backed by a *uint.
+type uintValue uint
+
+func newUintValue(val uint, p *uint) *uintValue {
+	*p = val
+	return (*uintValue)(p)
+}
+
+func (i *uintValue) Set(val string) error {
+	v, err := strconv.ParseUint(val, 0, strconv.IntSize)
+	if err != nil {
+		return err
+	}
+	*i = uintValue(v)
+	return nil
+}
+func (i *uintValue) String() string   { return strconv.FormatUint(uint64(*i), 10) }
+func (i *uintValue) IsBoolFlag() bool { return false }
+func (i *uintValue) typeName() string { return "uint" }
+
+// uint64Value is a Value backed by a *uint64.
+type uint64Value uint64
+
+func newUint64Value(val uint64, p *uint64) *uint64Value {
+	*p = val
+	return (*uint64Value)(p)
+}
+
+func (i *uint64Value) Set(val string) error {
+	v, err := strconv.ParseUint(val, 0, 64)
+	if err != nil {
+		return err
+	}
+	*i = uint64Value(v)
+	return nil
+}
+func (i *uint64Value) String() string   { return strconv.FormatUint(uint64(*i), 10) }
+func (i *uint64Value) IsBoolFlag() bool { return false }
+func (i *uint64Value) typeName() string { return "uint64" }
+
+// durationValue is a Value backed by a *time.Duration, parsed with
+// time.ParseDuration.
+type durationValue time.Duration
+
+func newDurationValue(val time.Duration, p *time.Duration) *durationValue {
+	*p = val
+	return (*durationValue)(p)
+}
+
+func (d *durationValue) Set(val string) error {
+	v, err := time.ParseDuration(val)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(v)
+	return nil
+}
+func (d *durationValue) String() string   { return time.Duration(*d).String() }
+func (d *durationValue) IsBoolFlag() bool { return false }
+func (d *durationValue) typeName() string { return "duration" }
+
+// funcValue turns a plain func(string) error into a Value, for flags whose
+// side effect matters more than a backing variable (e.g. repeated flags
+// that append to a log, or flags that validate and transform in one step).
+type funcValue func(string) error
+
+func (f funcValue) Set(val string) error { return f(val) }
+func (f funcValue) String() string       { return "" }
+func (f funcValue) IsBoolFlag() bool     { return false }
+
+// boolFuncValue is funcValue's boolean-flag counterpart: it is settable by
+// bare presence (e.g. -v) the same way BoolVar flags are.
+type boolFuncValue func(string) error
+
+func (f boolFuncValue) Set(val string) error { return f(val) }
+func (f boolFuncValue) String() string       { return "" }
+func (f boolFuncValue) IsBoolFlag() bool     { return true }
+
+// valueTypeName renders a flag's type label for Usage, or "" if the Value
+// doesn't advertise one (e.g. FuncVar/BoolFuncVar flags).
+func valueTypeName(v Value) string {
+	if tn, ok := v.(typeNamer); ok {
+		return tn.typeName()
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuiltinValueTypes(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewParser(out)
+
+	var ratio float64
+	var id int64
+	var limit uint
+	var total uint64
+	var timeout time.Duration
+
+	p.Float64Var(&ratio, "r", "ratio", 0.5, "Sampling ratio")
+	p.Int64Var(&id, "", "id", 0, "Resource ID")
+	p.UintVar(&limit, "", "limit", 10, "Max items")
+	p.Uint64Var(&total, "", "total", 0, "Total count")
+	p.DurationVar(&timeout, "t", "timeout", time.Second, "Request timeout")
+
+	err := p.Parse([]string{"-r=0.75", "--id=42", "--limit=100", "--total=9999999999", "-t=2m"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ratio != 0.75 {
+		t.Errorf("Expected ratio 0.75, got %v", ratio)
+	}
+	if id != 42 {
+		t.Errorf("Expected id 42, got %d", id)
+	}
+	if limit != 100 {
+		t.Errorf("Expected limit 100, got %d", limit)
+	}
+	if total != 9999999999 {
+		t.Errorf("Expected total 9999999999, got %d", total)
+	}
+	if timeout != 2*time.Minute {
+		t.Errorf("Expected timeout 2m, got %v", timeout)
+	}
+}
+
+func TestDurationVarInvalid(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var timeout time.Duration
+	p.DurationVar(&timeout, "t", "timeout", time.Second, "Request timeout")
+
+	err := p.Parse([]string{"-t=notaduration"})
+	if err == nil || !strings.Contains(err.Error(), "invalid value for flag") {
+		t.Fatalf("Expected invalid value error, got %v", err)
+	}
+}
+
+func TestVarCustomValue(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var level logLevel
+	p.Var(&level, "l", "level", "Log level")
+
+	if err := p.Parse([]string{"--level=debug"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if level != logLevelDebug {
+		t.Errorf("Expected debug level, got %v", level)
+	}
+}
+
+func TestFuncVar(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var tags []string
+	p.FuncVar("t", "tag", "Add a tag", func(val string) error {
+		tags = append(tags, val)
+		return nil
+	})
+
+	if err := p.Parse([]string{"-t", "foo", "--tag=bar"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tags) != 2 || tags[0] != "foo" || tags[1] != "bar" {
+		t.Errorf("Expected fn invoked once per occurrence [foo bar], got %v", tags)
+	}
+}
+
+func TestBoolFuncVar(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var called string
+	p.BoolFuncVar("v", "verbose", "Enable verbose logging", func(val string) error {
+		called = val
+		return nil
+	})
+
+	if err := p.Parse([]string{"-v"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if called != "true" {
+		t.Errorf("Expected fn called with 'true', got %q", called)
+	}
+}
+
+// logLevel is a minimal user-defined Value used to exercise Parser.Var.
+type logLevel int
+
+const (
+	logLevelInfo logLevel = iota
+	logLevelDebug
+)
+
+func (l *logLevel) String() string {
+	if *l == logLevelDebug {
+		return "debug"
+	}
+	return "info"
+}
+
+func (l *logLevel) Set(val string) error {
+	switch val {
+	case "debug":
+		*l = logLevelDebug
+	case "info":
+		*l = logLevelInfo
+	default:
+		return fmt.Errorf("invalid log level: %s", val)
+	}
+	return nil
+}
+
+func (l *logLevel) IsBoolFlag() bool { return false }
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDoubleDashTerminatesOptions(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var verbose bool
+	p.BoolVar(&verbose, "v", "verbose", false, "Verbose output")
+
+	if err := p.Parse([]string{"--", "-v", "--verbose", "file.txt"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if verbose {
+		t.Errorf("Expected -v after -- to be treated as positional, not set")
+	}
+	if !reflect.DeepEqual(p.Args(), []string{"-v", "--verbose", "file.txt"}) {
+		t.Errorf("Expected all tokens after -- to be positional, got %v", p.Args())
+	}
+}
+
+func TestDoubleDashMixedWithFlags(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var name string
+	p.StringVar(&name, "n", "name", "", "Name")
+
+	if err := p.Parse([]string{"-n", "Jane", "--", "-n", "Leftover"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "Jane" {
+		t.Errorf("Expected name 'Jane', got %s", name)
+	}
+	if !reflect.DeepEqual(p.Args(), []string{"-n", "Leftover"}) {
+		t.Errorf("Expected positional [-n Leftover], got %v", p.Args())
+	}
+}
+
+func TestDoubleDashDoesNotTriggerHelpForLiteralHelpToken(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+
+	err := p.Parse([]string{"--", "--help"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(p.Args(), []string{"--help"}) {
+		t.Errorf("Expected positional [--help], got %v", p.Args())
+	}
+
+	err = p.Parse([]string{"--", "-h"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(p.Args(), []string{"-h"}) {
+		t.Errorf("Expected positional [-h], got %v", p.Args())
+	}
+}
+
+func TestStackedBoolOnlyShortFlags(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var x, y, z bool
+	p.BoolVar(&x, "x", "xopt", false, "X")
+	p.BoolVar(&y, "y", "yopt", false, "Y")
+	p.BoolVar(&z, "z", "zopt", false, "Z")
+
+	if err := p.Parse([]string{"-xyz"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !x || !y || !z {
+		t.Errorf("Expected x/y/z all true, got %t/%t/%t", x, y, z)
+	}
+}
+
+func TestVisitAllVisitsEveryFlag(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var name string
+	var verbose bool
+	p.StringVar(&name, "n", "name", "anon", "Name")
+	p.BoolVar(&verbose, "v", "verbose", false, "Verbose")
+
+	if err := p.Parse([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var seen []string
+	p.VisitAll(func(shortName, longName string, value Value) {
+		seen = append(seen, longName)
+	})
+	if !reflect.DeepEqual(seen, []string{"name", "verbose"}) {
+		t.Errorf("Expected [name verbose], got %v", seen)
+	}
+}
+
+func TestVisitOnlyVisitsSetFlags(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var name string
+	var verbose bool
+	p.StringVar(&name, "n", "name", "anon", "Name")
+	p.BoolVar(&verbose, "v", "verbose", false, "Verbose")
+
+	if err := p.Parse([]string{"-v"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var seen []string
+	p.Visit(func(shortName, longName string, value Value) {
+		seen = append(seen, longName)
+	})
+	if !reflect.DeepEqual(seen, []string{"verbose"}) {
+		t.Errorf("Expected only [verbose] visited, got %v", seen)
+	}
+}
+
+func TestVisitIncludesEnvResolvedFlags(t *testing.T) {
+	p := NewParser(&bytes.Buffer{})
+	var host string
+	p.StringVarE(&host, "", "host", "GOARGS_TEST_VISIT_HOST", "localhost", "Host", false)
+
+	t.Setenv("GOARGS_TEST_VISIT_HOST", "example.com")
+
+	if err := p.Parse([]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var seen []string
+	p.Visit(func(shortName, longName string, value Value) {
+		seen = append(seen, longName)
+	})
+	if !reflect.DeepEqual(seen, []string{"host"}) {
+		t.Errorf("Expected env-resolved flag to be visited, got %v", seen)
+	}
+}